@@ -0,0 +1,20 @@
+package paypalsdk
+
+import "net/http"
+
+// Client represents a PayPal REST API client bound to a single APIBase
+// (live or sandbox).
+type Client struct {
+	APIBase string
+
+	// WebhookCertHTTPClient is used to fetch PAYPAL-CERT-URL certificates for
+	// VerifyWebhookSignatureLocal. Defaults to http.DefaultClient, so callers
+	// on high-throughput webhook receivers can supply one with their own
+	// timeouts/connection pooling.
+	WebhookCertHTTPClient *http.Client
+
+	// WebhookURLPolicy governs which URLs CreateWebhook and SetWebhook will
+	// accept. The zero value is secure-by-default: HTTPS only, no loopback,
+	// link-local, private, CGNAT, or metadata-service addresses.
+	WebhookURLPolicy WebhookURLPolicy
+}
@@ -1,12 +1,17 @@
 package paypalsdk
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"net/url"
+	"reflect"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -16,6 +21,10 @@ import (
 //
 // Endpoint: POST /v1/notifications/webhooks
 func (c *Client) CreateWebhook(w Webhook) (*Webhook, error) {
+	if err := c.checkWebhookURL(w.URL); err != nil {
+		return &Webhook{}, err
+	}
+
 	url := fmt.Sprintf("%s/v1/notifications/webhooks", c.APIBase)
 	req, err := c.NewRequest("POST", url, w)
 	if err != nil {
@@ -85,6 +94,10 @@ func (c *Client) SetWebhook(w Webhook) error {
 		return fmt.Errorf("paypalsdk: no ID specified for Webhook")
 	}
 
+	if err := c.checkWebhookURL(w.URL); err != nil {
+		return err
+	}
+
 	url := fmt.Sprintf("%s/v1/notifications/webhooks/%s", c.APIBase, w.ID)
 
 	p := []WebhookPatch{
@@ -201,13 +214,45 @@ func (c *Client) ResendWebhookEvent(eventID string, webhookIDs []string) (*Event
 	return &e, nil
 }
 
+// GetWebhookEventsFilter narrows the results of GetWebhookEvents and
+// GetWebhookEventsIter. All fields are optional; zero values are omitted
+// from the request.
+type GetWebhookEventsFilter struct {
+	PageSize      int
+	StartTime     time.Time
+	EndTime       time.Time
+	TransactionID string
+	EventType     string
+	// SortBy is the field PayPal sorts results by, e.g. "create_time".
+	SortBy string
+	// SortOrder is "asc" or "desc".
+	SortOrder string
+}
+
 // GetWebhookEvents get event notification details
 //
 // Endpoint: GET /v1/notifications/webhook-events
 func (c *Client) GetWebhookEvents(f GetWebhookEventsFilter) (*[]Event, error) {
-	var e []Event
+	var resp webhookEventsResponse
+
+	url := fmt.Sprintf("%s/v1/notifications/webhook-events?%s", c.APIBase, buildWebhookEventsQuery(f).Encode())
+	req, err := http.NewRequest("GET", url, nil)
+
+	if err != nil {
+		return &resp.Events, err
+	}
+
+	if err = c.SendWithAuth(req, &resp); err != nil {
+		return &resp.Events, err
+	}
+
+	return &resp.Events, nil
+}
 
-	var qs url.Values
+// buildWebhookEventsQuery turns a GetWebhookEventsFilter into the query
+// string GetWebhookEvents and GetWebhookEventsIter send to PayPal.
+func buildWebhookEventsQuery(f GetWebhookEventsFilter) url.Values {
+	qs := url.Values{}
 	if f.PageSize != 0 {
 		qs.Set("page_size", strconv.FormatInt(int64(f.PageSize), 10))
 	}
@@ -223,21 +268,103 @@ func (c *Client) GetWebhookEvents(f GetWebhookEventsFilter) (*[]Event, error) {
 	if f.EventType != "" {
 		qs.Set("event_type", f.EventType)
 	}
+	if f.SortBy != "" {
+		qs.Set("sort_by", f.SortBy)
+	}
+	if f.SortOrder != "" {
+		qs.Set("sort_order", f.SortOrder)
+	}
+	return qs
+}
 
-	url := fmt.Sprintf("%s/v1/notifications/webhook-events?%s", c.APIBase, qs.Encode())
-	req, err := http.NewRequest("GET", url, nil)
+// webhookEventsResponse is the paginated envelope PayPal returns for
+// GET /v1/notifications/webhook-events, including the HAL links used to
+// fetch the next page.
+type webhookEventsResponse struct {
+	Events []Event `json:"events"`
+	Links  []Link  `json:"links"`
+}
 
-	if err != nil {
-		return &e, err
+// WebhookEventIterator streams pages of webhook events matching a
+// GetWebhookEventsFilter, following the HAL "next" link PayPal returns
+// instead of requiring the caller to manage page tokens.
+//
+// A WebhookEventIterator is not safe for concurrent use.
+type WebhookEventIterator struct {
+	client *Client
+	filter GetWebhookEventsFilter
+
+	pending []Event
+	nextURL string
+	done    bool
+	err     error
+}
+
+// GetWebhookEventsIter returns a WebhookEventIterator over the webhook
+// events matching f. Pages are fetched lazily as Next is called, so long
+// histories can be streamed without loading everything into memory.
+func (c *Client) GetWebhookEventsIter(f GetWebhookEventsFilter) *WebhookEventIterator {
+	return &WebhookEventIterator{client: c, filter: f}
+}
+
+// Next returns the next Event, fetching another page from PayPal if the
+// current page has been exhausted. It returns (nil, nil) once the iterator
+// is exhausted; callers should check Err afterwards to distinguish that from
+// a fetch error.
+func (it *WebhookEventIterator) Next(ctx context.Context) (*Event, error) {
+	if it.err != nil {
+		return nil, it.err
 	}
 
-	if err = c.SendWithAuth(req, &e); err != nil {
-		return &e, err
+	for len(it.pending) == 0 {
+		if it.done {
+			return nil, nil
+		}
+		if err := it.fetch(ctx); err != nil {
+			it.err = err
+			return nil, err
+		}
 	}
 
+	e := it.pending[0]
+	it.pending = it.pending[1:]
 	return &e, nil
 }
 
+// Err returns the first error encountered by Next, if any.
+func (it *WebhookEventIterator) Err() error {
+	return it.err
+}
+
+func (it *WebhookEventIterator) fetch(ctx context.Context) error {
+	reqURL := it.nextURL
+	if reqURL == "" {
+		reqURL = fmt.Sprintf("%s/v1/notifications/webhook-events?%s", it.client.APIBase, buildWebhookEventsQuery(it.filter).Encode())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return err
+	}
+
+	var resp webhookEventsResponse
+	if err := it.client.SendWithAuth(req, &resp); err != nil {
+		return err
+	}
+
+	it.pending = resp.Events
+	it.nextURL = ""
+	for _, l := range resp.Links {
+		if l.Rel == "next" {
+			it.nextURL = l.Href
+		}
+	}
+	if it.nextURL == "" {
+		it.done = true
+	}
+	return nil
+}
+
 // SimulateWebhookEvent simulates a webhook event
 //
 // Endpoint: POST /v1/notifications/simulate-event
@@ -282,3 +409,217 @@ func (c *Client) VerifyWebhookSignature(r WebhookRequest) (*VerificationStatus,
 
 	return &e, nil
 }
+
+// Middleware wraps an http.Handler with additional behaviour (logging,
+// auth, rate limiting, ...) and is chained in the order passed to
+// WithMiddleware, outermost first.
+type Middleware func(http.Handler) http.Handler
+
+// IdempotencyStore records which webhook event IDs a WebhookMux has claimed
+// for dispatch, so a redelivery from PayPal (sequential or concurrent) isn't
+// run through the handler twice. TryClaim must be atomic: concurrent calls
+// for the same eventID must result in exactly one true. Release un-claims an
+// eventID after a failed dispatch, so PayPal's retry still reaches the
+// handler instead of being silently dropped.
+type IdempotencyStore interface {
+	// TryClaim atomically marks eventID as claimed and reports whether this
+	// call was the one that claimed it.
+	TryClaim(eventID string) bool
+	// Release un-claims eventID, e.g. after a failed dispatch.
+	Release(eventID string)
+}
+
+// MemoryIdempotencyStore is an IdempotencyStore backed by an in-process map.
+// It is the WebhookMux default and does not survive restarts.
+type MemoryIdempotencyStore struct {
+	mu     sync.Mutex
+	claims map[string]struct{}
+}
+
+// NewMemoryIdempotencyStore creates an empty MemoryIdempotencyStore.
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{claims: make(map[string]struct{})}
+}
+
+// TryClaim implements IdempotencyStore.
+func (s *MemoryIdempotencyStore) TryClaim(eventID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.claims[eventID]; ok {
+		return false
+	}
+	s.claims[eventID] = struct{}{}
+	return true
+}
+
+// Release implements IdempotencyStore.
+func (s *MemoryIdempotencyStore) Release(eventID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.claims, eventID)
+}
+
+// webhookHandler is the reflected form of a handler registered with
+// WebhookMux.On: func(ctx context.Context, resource *T) error for some
+// resource type T.
+type webhookHandler struct {
+	fn           reflect.Value
+	resourceType reflect.Type
+}
+
+// WebhookMux is an http.Handler for a single PayPal webhook endpoint. It
+// verifies the inbound signature, decodes Event.Resource into the type
+// registered for the event's event_type, and dispatches it to the matching
+// handler.
+//
+// WebhookMux is safe for concurrent use by multiple goroutines once
+// construction (calls to On/OnAny/WithMiddleware) has finished.
+type WebhookMux struct {
+	Client    *Client
+	WebhookID string
+
+	// Idempotency de-duplicates redeliveries by Event.ID. NewWebhookMux sets
+	// it to a MemoryIdempotencyStore; if it is nil, events are not
+	// de-duplicated.
+	Idempotency IdempotencyStore
+
+	handlers   map[string]webhookHandler
+	anyHandler func(ctx context.Context, event *Event) error
+	middleware []Middleware
+	metrics    sync.Map // event_type -> *uint64
+}
+
+// NewWebhookMux creates a WebhookMux that verifies events against webhookID
+// using c.
+func NewWebhookMux(c *Client, webhookID string) *WebhookMux {
+	return &WebhookMux{
+		Client:      c,
+		WebhookID:   webhookID,
+		Idempotency: NewMemoryIdempotencyStore(),
+		handlers:    make(map[string]webhookHandler),
+	}
+}
+
+// On registers handler to be called for events whose event_type matches
+// eventType. handler must have the shape func(ctx context.Context, resource
+// *T) error for some resource type T, e.g.:
+//
+//	mux.On("PAYMENT.SALE.COMPLETED", func(ctx context.Context, s *Sale) error { ... })
+//
+// On panics if handler does not have that shape, since registrations happen
+// once at startup.
+func (mux *WebhookMux) On(eventType string, handler interface{}) {
+	fn := reflect.ValueOf(handler)
+	t := fn.Type()
+
+	if t.Kind() != reflect.Func || t.NumIn() != 2 || t.NumOut() != 1 ||
+		t.In(0) != reflect.TypeOf((*context.Context)(nil)).Elem() ||
+		t.In(1).Kind() != reflect.Ptr ||
+		t.Out(0) != reflect.TypeOf((*error)(nil)).Elem() {
+		panic("paypalsdk: webhook handler must have the shape func(context.Context, *T) error")
+	}
+
+	mux.handlers[eventType] = webhookHandler{fn: fn, resourceType: t.In(1).Elem()}
+}
+
+// OnAny registers a fallback handler invoked for any event that has no
+// handler registered via On.
+func (mux *WebhookMux) OnAny(handler func(ctx context.Context, event *Event) error) {
+	mux.anyHandler = handler
+}
+
+// WithMiddleware appends middleware to the chain applied around ServeHTTP,
+// outermost first, and returns mux for chaining.
+func (mux *WebhookMux) WithMiddleware(middleware ...Middleware) *WebhookMux {
+	mux.middleware = append(mux.middleware, middleware...)
+	return mux
+}
+
+// Count returns the number of events dispatched so far for eventType.
+func (mux *WebhookMux) Count(eventType string) uint64 {
+	v, ok := mux.metrics.Load(eventType)
+	if !ok {
+		return 0
+	}
+	return atomic.LoadUint64(v.(*uint64))
+}
+
+func (mux *WebhookMux) incrCount(eventType string) {
+	v, _ := mux.metrics.LoadOrStore(eventType, new(uint64))
+	atomic.AddUint64(v.(*uint64), 1)
+}
+
+// ServeHTTP implements http.Handler. It verifies the webhook signature,
+// decodes the event, and dispatches it to the handler registered for its
+// event_type (or OnAny if none matches).
+func (mux *WebhookMux) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var handler http.Handler = http.HandlerFunc(mux.serveVerified)
+	for i := len(mux.middleware) - 1; i >= 0; i-- {
+		handler = mux.middleware[i](handler)
+	}
+	handler.ServeHTTP(w, req)
+}
+
+func (mux *WebhookMux) serveVerified(w http.ResponseWriter, req *http.Request) {
+	ok, err := mux.Client.VerifyWebhookSignatureLocal(req, mux.WebhookID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("paypalsdk: webhook signature verification failed: %v", err), http.StatusBadRequest)
+		return
+	}
+	if !ok {
+		http.Error(w, "paypalsdk: webhook signature is invalid", http.StatusUnauthorized)
+		return
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, "paypalsdk: unable to read request body", http.StatusBadRequest)
+		return
+	}
+
+	var event Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		http.Error(w, "paypalsdk: unable to decode event", http.StatusBadRequest)
+		return
+	}
+
+	if mux.Idempotency != nil && !mux.Idempotency.TryClaim(event.ID) {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	mux.incrCount(event.EventType)
+
+	if err := mux.dispatch(req.Context(), &event); err != nil {
+		if mux.Idempotency != nil {
+			mux.Idempotency.Release(event.ID)
+		}
+		http.Error(w, fmt.Sprintf("paypalsdk: handler error: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (mux *WebhookMux) dispatch(ctx context.Context, event *Event) error {
+	h, ok := mux.handlers[event.EventType]
+	if !ok {
+		if mux.anyHandler != nil {
+			return mux.anyHandler(ctx, event)
+		}
+		return nil
+	}
+
+	resource := reflect.New(h.resourceType)
+	if err := json.Unmarshal(event.Resource, resource.Interface()); err != nil {
+		return fmt.Errorf("paypalsdk: unable to decode resource for event_type %q: %w", event.EventType, err)
+	}
+
+	out := h.fn.Call([]reflect.Value{reflect.ValueOf(ctx), resource})
+	if errVal := out[0].Interface(); errVal != nil {
+		return errVal.(error)
+	}
+	return nil
+}
@@ -0,0 +1,359 @@
+package paypalsdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// DeliveryAction identifies which underlying webhook event API call a
+// DeliveryAttempt should make.
+type DeliveryAction string
+
+const (
+	// DeliveryActionResend drives ResendWebhookEvent.
+	DeliveryActionResend DeliveryAction = "resend"
+	// DeliveryActionSimulate drives SimulateWebhookEvent.
+	DeliveryActionSimulate DeliveryAction = "simulate"
+)
+
+// DeliveryStatus is the outcome of the most recent attempt to carry out a
+// DeliveryAttempt.
+type DeliveryStatus string
+
+const (
+	// DeliveryStatusPending means the attempt has not yet been tried, or is
+	// waiting for NextRetryAt before being tried again.
+	DeliveryStatusPending DeliveryStatus = "pending"
+	// DeliveryStatusSucceeded means the underlying API call returned without error.
+	DeliveryStatusSucceeded DeliveryStatus = "succeeded"
+	// DeliveryStatusFailed means every allowed attempt was exhausted without success.
+	DeliveryStatusFailed DeliveryStatus = "failed"
+)
+
+// BackoffPolicy configures the exponential backoff with jitter used between
+// delivery attempts.
+type BackoffPolicy struct {
+	// Base is the delay before the first retry.
+	Base time.Duration
+	// Factor multiplies the previous delay to compute the next one.
+	Factor float64
+	// Max caps the computed delay, regardless of attempt count.
+	Max time.Duration
+	// MaxAttempts is the total number of attempts (including the first)
+	// before an entry is marked DeliveryStatusFailed.
+	MaxAttempts int
+	// Jitter is the fraction (0-1) of the computed delay that is randomized,
+	// to avoid many entries retrying in lockstep.
+	Jitter float64
+}
+
+// DefaultBackoffPolicy is a reasonable starting point for retrying webhook
+// deliveries: 1s, 2s, 4s, ... up to 5 minutes, for 10 attempts total.
+var DefaultBackoffPolicy = BackoffPolicy{
+	Base:        1 * time.Second,
+	Factor:      2,
+	Max:         5 * time.Minute,
+	MaxAttempts: 10,
+	Jitter:      0.2,
+}
+
+// next returns the delay to wait before the given attempt number (1-indexed,
+// the attempt that just failed) is retried.
+func (p BackoffPolicy) next(attempt int) time.Duration {
+	delay := float64(p.Base)
+	for i := 1; i < attempt; i++ {
+		delay *= p.Factor
+	}
+	if max := float64(p.Max); delay > max {
+		delay = max
+	}
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (rand.Float64()*2 - 1)
+		if delay < 0 {
+			delay = 0
+		}
+	}
+	return time.Duration(delay)
+}
+
+// DeliveryAttempt records one unit of work for the delivery queue: either a
+// resend of an existing event to a set of webhooks, or a re-simulation of an
+// event, along with the history of tries made so far.
+type DeliveryAttempt struct {
+	ID         string
+	Action     DeliveryAction
+	EventID    string
+	WebhookIDs []string
+	Simulate   SimulateEventReq
+
+	Status       DeliveryStatus
+	Attempts     int
+	LastError    string
+	LastLatency  time.Duration
+	ResponseID   string
+	ResponseBody []byte
+	NextRetryAt  time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// DeliveryStore persists DeliveryAttempts and answers the queries the Worker
+// needs to find due work. Implementations must be safe for concurrent use.
+//
+// The in-memory implementation below is suitable for tests and single-process
+// use; production deployments are expected to provide a SQL or Redis backed
+// implementation.
+type DeliveryStore interface {
+	Save(ctx context.Context, a *DeliveryAttempt) error
+	Get(ctx context.Context, id string) (*DeliveryAttempt, error)
+	Due(ctx context.Context, now time.Time, limit int) ([]*DeliveryAttempt, error)
+}
+
+// MemoryDeliveryStore is a DeliveryStore backed by an in-process map. It does
+// not survive restarts and is intended for tests and small single-process
+// deployments.
+type MemoryDeliveryStore struct {
+	mu    sync.Mutex
+	byID  map[string]*DeliveryAttempt
+	order []string
+}
+
+// NewMemoryDeliveryStore creates an empty MemoryDeliveryStore.
+func NewMemoryDeliveryStore() *MemoryDeliveryStore {
+	return &MemoryDeliveryStore{
+		byID: make(map[string]*DeliveryAttempt),
+	}
+}
+
+// Save implements DeliveryStore.
+func (s *MemoryDeliveryStore) Save(ctx context.Context, a *DeliveryAttempt) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.byID[a.ID]; !ok {
+		s.order = append(s.order, a.ID)
+	}
+	dup := *a
+	s.byID[a.ID] = &dup
+	return nil
+}
+
+// Get implements DeliveryStore.
+func (s *MemoryDeliveryStore) Get(ctx context.Context, id string) (*DeliveryAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.byID[id]
+	if !ok {
+		return nil, nil
+	}
+	dup := *a
+	return &dup, nil
+}
+
+// Due implements DeliveryStore, returning up to limit pending attempts whose
+// NextRetryAt has passed, oldest first. A limit of 0 or less returns every
+// due attempt.
+func (s *MemoryDeliveryStore) Due(ctx context.Context, now time.Time, limit int) ([]*DeliveryAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []*DeliveryAttempt
+	for _, id := range s.order {
+		a := s.byID[id]
+		if a.Status != DeliveryStatusPending || a.NextRetryAt.After(now) {
+			continue
+		}
+		dup := *a
+		due = append(due, &dup)
+		if limit > 0 && len(due) >= limit {
+			break
+		}
+	}
+	return due, nil
+}
+
+// WebhookDeliveryQueue wraps ResendWebhookEvent and SimulateWebhookEvent with
+// a persistent retry pipeline: each call to Resend or Simulate is recorded as
+// a DeliveryAttempt in Store, and Worker.Run drives attempts to completion
+// with exponential backoff.
+type WebhookDeliveryQueue struct {
+	Client  *Client
+	Store   DeliveryStore
+	Backoff BackoffPolicy
+
+	// OnSuccess and OnFailure, when set, are invoked by the worker after an
+	// attempt resolves to DeliveryStatusSucceeded or DeliveryStatusFailed.
+	OnSuccess func(*DeliveryAttempt)
+	OnFailure func(*DeliveryAttempt)
+
+	// resendFunc and simulateFunc perform the underlying PayPal API calls for
+	// an attempt. NewWebhookDeliveryQueue points them at Client's own methods;
+	// tests substitute fakes here instead of needing a real Client.
+	resendFunc   func(eventID string, webhookIDs []string) (*Event, error)
+	simulateFunc func(r SimulateEventReq) (*Event, error)
+
+	idMu  sync.Mutex
+	idSeq int64
+}
+
+// NewWebhookDeliveryQueue creates a WebhookDeliveryQueue backed by store,
+// using c to perform the underlying PayPal API calls and
+// DefaultBackoffPolicy for retry timing.
+func NewWebhookDeliveryQueue(c *Client, store DeliveryStore) *WebhookDeliveryQueue {
+	return &WebhookDeliveryQueue{
+		Client:       c,
+		Store:        store,
+		Backoff:      DefaultBackoffPolicy,
+		resendFunc:   c.ResendWebhookEvent,
+		simulateFunc: c.SimulateWebhookEvent,
+	}
+}
+
+func (q *WebhookDeliveryQueue) nextID() string {
+	q.idMu.Lock()
+	defer q.idMu.Unlock()
+	q.idSeq++
+	return fmt.Sprintf("delivery-%d-%d", time.Now().UnixNano(), q.idSeq)
+}
+
+// Resend enqueues a resend of eventID to webhookIDs and returns the
+// DeliveryAttempt tracking it. The actual PayPal API call happens
+// asynchronously, driven by Worker.Run.
+func (q *WebhookDeliveryQueue) Resend(ctx context.Context, eventID string, webhookIDs []string) (*DeliveryAttempt, error) {
+	a := &DeliveryAttempt{
+		ID:          q.nextID(),
+		Action:      DeliveryActionResend,
+		EventID:     eventID,
+		WebhookIDs:  webhookIDs,
+		Status:      DeliveryStatusPending,
+		NextRetryAt: time.Now(),
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if err := q.Store.Save(ctx, a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Simulate enqueues a webhook event simulation and returns the
+// DeliveryAttempt tracking it. The actual PayPal API call happens
+// asynchronously, driven by Worker.Run.
+func (q *WebhookDeliveryQueue) Simulate(ctx context.Context, r SimulateEventReq) (*DeliveryAttempt, error) {
+	a := &DeliveryAttempt{
+		ID:          q.nextID(),
+		Action:      DeliveryActionSimulate,
+		Simulate:    r,
+		Status:      DeliveryStatusPending,
+		NextRetryAt: time.Now(),
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if err := q.Store.Save(ctx, a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Worker pulls due DeliveryAttempts from a WebhookDeliveryQueue's Store and
+// carries them out, applying the queue's BackoffPolicy on failure.
+type Worker struct {
+	Queue        *WebhookDeliveryQueue
+	PollInterval time.Duration
+	BatchSize    int
+}
+
+// NewWorker creates a Worker for queue with a 5 second poll interval and a
+// batch size of 10.
+func NewWorker(queue *WebhookDeliveryQueue) *Worker {
+	return &Worker{
+		Queue:        queue,
+		PollInterval: 5 * time.Second,
+		BatchSize:    10,
+	}
+}
+
+// Run polls the queue's Store for due attempts and carries them out until ctx
+// is cancelled.
+func (w *Worker) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.runOnce(ctx); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Worker) runOnce(ctx context.Context) error {
+	due, err := w.Queue.Store.Due(ctx, time.Now(), w.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range due {
+		w.attempt(ctx, a)
+	}
+	return nil
+}
+
+func (w *Worker) attempt(ctx context.Context, a *DeliveryAttempt) {
+	start := time.Now()
+	var (
+		event *Event
+		err   error
+	)
+
+	switch a.Action {
+	case DeliveryActionResend:
+		event, err = w.Queue.resendFunc(a.EventID, a.WebhookIDs)
+	case DeliveryActionSimulate:
+		event, err = w.Queue.simulateFunc(a.Simulate)
+	}
+
+	a.Attempts++
+	a.LastLatency = time.Since(start)
+	a.UpdatedAt = time.Now()
+
+	if event != nil {
+		a.ResponseID = event.ID
+		if body, marshalErr := json.Marshal(event); marshalErr == nil {
+			a.ResponseBody = body
+		}
+	}
+
+	if err == nil {
+		a.Status = DeliveryStatusSucceeded
+		a.LastError = ""
+		_ = w.Queue.Store.Save(ctx, a)
+		if w.Queue.OnSuccess != nil {
+			w.Queue.OnSuccess(a)
+		}
+		return
+	}
+
+	a.LastError = err.Error()
+	if a.Attempts >= w.Queue.Backoff.MaxAttempts {
+		a.Status = DeliveryStatusFailed
+		_ = w.Queue.Store.Save(ctx, a)
+		if w.Queue.OnFailure != nil {
+			w.Queue.OnFailure(a)
+		}
+		return
+	}
+
+	a.NextRetryAt = time.Now().Add(w.Queue.Backoff.next(a.Attempts))
+	_ = w.Queue.Store.Save(ctx, a)
+}
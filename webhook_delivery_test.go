@@ -0,0 +1,239 @@
+package paypalsdk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBackoffPolicyNext(t *testing.T) {
+	p := BackoffPolicy{Base: time.Second, Factor: 2, Max: 10 * time.Second, Jitter: 0}
+
+	cases := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 8 * time.Second},
+		{5, 10 * time.Second}, // capped by Max
+	}
+
+	for _, tc := range cases {
+		if got := p.next(tc.attempt); got != tc.want {
+			t.Errorf("next(%d) = %v, want %v", tc.attempt, got, tc.want)
+		}
+	}
+}
+
+func TestMemoryDeliveryStoreDue(t *testing.T) {
+	store := NewMemoryDeliveryStore()
+	ctx := context.Background()
+	now := time.Now()
+
+	due := &DeliveryAttempt{ID: "due", Status: DeliveryStatusPending, NextRetryAt: now.Add(-time.Minute)}
+	notYet := &DeliveryAttempt{ID: "not-yet", Status: DeliveryStatusPending, NextRetryAt: now.Add(time.Hour)}
+	done := &DeliveryAttempt{ID: "done", Status: DeliveryStatusSucceeded, NextRetryAt: now.Add(-time.Minute)}
+
+	for _, a := range []*DeliveryAttempt{due, notYet, done} {
+		if err := store.Save(ctx, a); err != nil {
+			t.Fatalf("Save(%s) error = %v", a.ID, err)
+		}
+	}
+
+	got, err := store.Due(ctx, now, 0)
+	if err != nil {
+		t.Fatalf("Due() error = %v", err)
+	}
+	if len(got) != 1 || got[0].ID != "due" {
+		t.Fatalf("Due() = %+v, want only %q", got, "due")
+	}
+}
+
+func TestMemoryDeliveryStoreGetReturnsCopy(t *testing.T) {
+	store := NewMemoryDeliveryStore()
+	ctx := context.Background()
+
+	a := &DeliveryAttempt{ID: "a", LastError: "original"}
+	if err := store.Save(ctx, a); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got, err := store.Get(ctx, "a")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	got.LastError = "mutated"
+
+	again, err := store.Get(ctx, "a")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if again.LastError != "original" {
+		t.Fatalf("Get() returned a shared reference: LastError = %q, want %q", again.LastError, "original")
+	}
+}
+
+// newTestWorker builds a Worker and its queue over a fresh
+// MemoryDeliveryStore, with no Client; each test stubs the queue's
+// resendFunc/simulateFunc itself, so behavior can be driven without a real
+// Client.
+func newTestWorker(t *testing.T, backoff BackoffPolicy) (*Worker, *WebhookDeliveryQueue) {
+	t.Helper()
+
+	queue := &WebhookDeliveryQueue{
+		Store:   NewMemoryDeliveryStore(),
+		Backoff: backoff,
+	}
+	worker := &Worker{Queue: queue, BatchSize: 10}
+	return worker, queue
+}
+
+func TestWorkerAttemptSucceeds(t *testing.T) {
+	worker, queue := newTestWorker(t, DefaultBackoffPolicy)
+
+	var onSuccessCalls int
+	queue.OnSuccess = func(a *DeliveryAttempt) { onSuccessCalls++ }
+	queue.resendFunc = func(eventID string, webhookIDs []string) (*Event, error) {
+		return &Event{ID: "WH-EVENT-1"}, nil
+	}
+
+	ctx := context.Background()
+	a, err := queue.Resend(ctx, "EVT-1", []string{"WH-1"})
+	if err != nil {
+		t.Fatalf("Resend() error = %v", err)
+	}
+
+	worker.attempt(ctx, a)
+
+	got, err := queue.Store.Get(ctx, a.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Status != DeliveryStatusSucceeded {
+		t.Fatalf("Status = %q, want %q", got.Status, DeliveryStatusSucceeded)
+	}
+	if got.Attempts != 1 {
+		t.Fatalf("Attempts = %d, want 1", got.Attempts)
+	}
+	if got.ResponseID != "WH-EVENT-1" {
+		t.Fatalf("ResponseID = %q, want %q", got.ResponseID, "WH-EVENT-1")
+	}
+	if onSuccessCalls != 1 {
+		t.Fatalf("OnSuccess called %d times, want 1", onSuccessCalls)
+	}
+}
+
+func TestWorkerAttemptRetriesThenSucceeds(t *testing.T) {
+	worker, queue := newTestWorker(t, BackoffPolicy{Base: time.Millisecond, Factor: 2, Max: time.Second, MaxAttempts: 3})
+
+	calls := 0
+	queue.simulateFunc = func(r SimulateEventReq) (*Event, error) {
+		calls++
+		if calls < 2 {
+			return nil, errors.New("temporary failure")
+		}
+		return &Event{ID: "WH-EVENT-2"}, nil
+	}
+
+	ctx := context.Background()
+	a, err := queue.Simulate(ctx, SimulateEventReq{EventType: "PAYMENT.SALE.COMPLETED"})
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+
+	worker.attempt(ctx, a)
+	got, err := queue.Store.Get(ctx, a.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Status != DeliveryStatusPending {
+		t.Fatalf("after first failed attempt, Status = %q, want %q", got.Status, DeliveryStatusPending)
+	}
+	if got.Attempts != 1 {
+		t.Fatalf("Attempts = %d, want 1", got.Attempts)
+	}
+	if !got.NextRetryAt.After(time.Now()) {
+		t.Fatal("NextRetryAt should be scheduled in the future after a failed attempt")
+	}
+
+	worker.attempt(ctx, got)
+	got, err = queue.Store.Get(ctx, a.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Status != DeliveryStatusSucceeded {
+		t.Fatalf("Status = %q, want %q", got.Status, DeliveryStatusSucceeded)
+	}
+	if got.Attempts != 2 {
+		t.Fatalf("Attempts = %d, want 2", got.Attempts)
+	}
+}
+
+func TestWorkerAttemptMarksFailedAfterMaxAttempts(t *testing.T) {
+	worker, queue := newTestWorker(t, BackoffPolicy{Base: time.Millisecond, Factor: 1, Max: time.Millisecond, MaxAttempts: 2})
+
+	var onFailureCalls int
+	queue.OnFailure = func(a *DeliveryAttempt) { onFailureCalls++ }
+	queue.resendFunc = func(eventID string, webhookIDs []string) (*Event, error) {
+		return nil, errors.New("permanent failure")
+	}
+
+	ctx := context.Background()
+	a, err := queue.Resend(ctx, "EVT-3", []string{"WH-1"})
+	if err != nil {
+		t.Fatalf("Resend() error = %v", err)
+	}
+
+	worker.attempt(ctx, a)
+	got, err := queue.Store.Get(ctx, a.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Status != DeliveryStatusPending {
+		t.Fatalf("after attempt 1, Status = %q, want %q", got.Status, DeliveryStatusPending)
+	}
+
+	worker.attempt(ctx, got)
+	got, err = queue.Store.Get(ctx, a.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Status != DeliveryStatusFailed {
+		t.Fatalf("after attempt 2 (MaxAttempts), Status = %q, want %q", got.Status, DeliveryStatusFailed)
+	}
+	if got.LastError != "permanent failure" {
+		t.Fatalf("LastError = %q, want %q", got.LastError, "permanent failure")
+	}
+	if onFailureCalls != 1 {
+		t.Fatalf("OnFailure called %d times, want 1", onFailureCalls)
+	}
+}
+
+func TestWorkerRunOnceProcessesDueAttempts(t *testing.T) {
+	worker, queue := newTestWorker(t, DefaultBackoffPolicy)
+
+	queue.resendFunc = func(eventID string, webhookIDs []string) (*Event, error) {
+		return &Event{ID: "WH-EVENT-4"}, nil
+	}
+
+	ctx := context.Background()
+	a, err := queue.Resend(ctx, "EVT-4", []string{"WH-1"})
+	if err != nil {
+		t.Fatalf("Resend() error = %v", err)
+	}
+
+	if err := worker.runOnce(ctx); err != nil {
+		t.Fatalf("runOnce() error = %v", err)
+	}
+
+	got, err := queue.Store.Get(ctx, a.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Status != DeliveryStatusSucceeded {
+		t.Fatalf("Status = %q, want %q", got.Status, DeliveryStatusSucceeded)
+	}
+}
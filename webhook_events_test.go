@@ -0,0 +1,56 @@
+package paypalsdk
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildWebhookEventsQuery(t *testing.T) {
+	f := GetWebhookEventsFilter{
+		PageSize:      50,
+		TransactionID: "txn-1",
+		EventType:     "PAYMENT.SALE.COMPLETED",
+		SortBy:        "create_time",
+		SortOrder:     "desc",
+	}
+
+	qs := buildWebhookEventsQuery(f)
+
+	want := map[string]string{
+		"page_size":      "50",
+		"transaction_id": "txn-1",
+		"event_type":     "PAYMENT.SALE.COMPLETED",
+		"sort_by":        "create_time",
+		"sort_order":     "desc",
+	}
+	for k, v := range want {
+		if got := qs.Get(k); got != v {
+			t.Errorf("qs.Get(%q) = %q, want %q", k, got, v)
+		}
+	}
+
+	if _, ok := qs["start_time"]; ok {
+		t.Error("start_time should be omitted when StartTime is zero")
+	}
+}
+
+func TestBuildWebhookEventsQueryOmitsZeroValues(t *testing.T) {
+	qs := buildWebhookEventsQuery(GetWebhookEventsFilter{})
+	if len(qs) != 0 {
+		t.Errorf("buildWebhookEventsQuery(zero value) = %v, want empty", qs)
+	}
+}
+
+func TestBuildWebhookEventsQueryTimeRange(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+
+	qs := buildWebhookEventsQuery(GetWebhookEventsFilter{StartTime: start, EndTime: end})
+
+	if got := qs.Get("start_time"); got != start.Format(time.RFC3339) {
+		t.Errorf("start_time = %q, want %q", got, start.Format(time.RFC3339))
+	}
+	if got := qs.Get("end_time"); got != end.Format(time.RFC3339) {
+		t.Errorf("end_time = %q, want %q", got, end.Format(time.RFC3339))
+	}
+}
@@ -0,0 +1,251 @@
+package paypalsdk
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+type testResource struct {
+	Amount string `json:"amount"`
+}
+
+// newSignedWebhookRequest builds an httptest request carrying a validly
+// signed webhook event, by seeding defaultWebhookCertCache directly so
+// VerifyWebhookSignatureLocal doesn't need a real PAYPAL-CERT-URL fetch.
+func newSignedWebhookRequest(t *testing.T, webhookID, eventID, eventType string, resource json.RawMessage) *http.Request {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+
+	certURL := fmt.Sprintf("https://api.paypal.com/v1/notifications/certs/CERT-%s", eventID)
+	defaultWebhookCertCache.set(certURL, &x509.Certificate{PublicKey: &key.PublicKey})
+
+	body, err := json.Marshal(struct {
+		ID        string          `json:"id"`
+		EventType string          `json:"event_type"`
+		Resource  json.RawMessage `json:"resource"`
+	}{ID: eventID, EventType: eventType, Resource: resource})
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	const transmissionID = "transmission-1"
+	const transmissionTime = "2026-07-26T00:00:00Z"
+	sig := signWebhookPayload(t, key, transmissionID, transmissionTime, webhookID, body)
+
+	req := httptest.NewRequest(http.MethodPost, "https://example.com/webhook", bytes.NewReader(body))
+	req.Header.Set("PAYPAL-TRANSMISSION-ID", transmissionID)
+	req.Header.Set("PAYPAL-TRANSMISSION-TIME", transmissionTime)
+	req.Header.Set("PAYPAL-TRANSMISSION-SIG", base64.StdEncoding.EncodeToString(sig))
+	req.Header.Set("PAYPAL-CERT-URL", certURL)
+	req.Header.Set("PAYPAL-AUTH-ALGO", "SHA256withRSA")
+	return req
+}
+
+func TestWebhookMuxDispatch(t *testing.T) {
+	mux := NewWebhookMux(&Client{}, "WH-1")
+
+	var got *testResource
+	mux.On("PAYMENT.SALE.COMPLETED", func(ctx context.Context, r *testResource) error {
+		got = r
+		return nil
+	})
+
+	event := &Event{
+		ID:        "WH-EVENT-1",
+		EventType: "PAYMENT.SALE.COMPLETED",
+		Resource:  json.RawMessage(`{"amount":"10.00"}`),
+	}
+
+	if err := mux.dispatch(context.Background(), event); err != nil {
+		t.Fatalf("dispatch() error = %v", err)
+	}
+	if got == nil || got.Amount != "10.00" {
+		t.Fatalf("handler received %+v, want amount 10.00", got)
+	}
+}
+
+func TestWebhookMuxDispatchFallsBackToOnAny(t *testing.T) {
+	mux := NewWebhookMux(&Client{}, "WH-1")
+
+	var gotID string
+	mux.OnAny(func(ctx context.Context, e *Event) error {
+		gotID = e.ID
+		return nil
+	})
+
+	event := &Event{ID: "WH-EVENT-2", EventType: "BILLING.SUBSCRIPTION.CANCELLED"}
+	if err := mux.dispatch(context.Background(), event); err != nil {
+		t.Fatalf("dispatch() error = %v", err)
+	}
+	if gotID != "WH-EVENT-2" {
+		t.Fatalf("OnAny handler got ID %q, want %q", gotID, "WH-EVENT-2")
+	}
+}
+
+func TestWebhookMuxServeHTTP(t *testing.T) {
+	mux := NewWebhookMux(&Client{}, "WH-1")
+
+	var gotAmount string
+	mux.On("PAYMENT.SALE.COMPLETED", func(ctx context.Context, r *testResource) error {
+		gotAmount = r.Amount
+		return nil
+	})
+
+	req := newSignedWebhookRequest(t, "WH-1", "WH-EVENT-3", "PAYMENT.SALE.COMPLETED", json.RawMessage(`{"amount":"10.00"}`))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("ServeHTTP() status = %d, want %d; body: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if gotAmount != "10.00" {
+		t.Fatalf("handler received amount %q, want %q", gotAmount, "10.00")
+	}
+	if got := mux.Count("PAYMENT.SALE.COMPLETED"); got != 1 {
+		t.Fatalf("Count() = %d, want 1", got)
+	}
+
+	// A redelivery of the same event must not re-run the handler.
+	gotAmount = ""
+	req2 := newSignedWebhookRequest(t, "WH-1", "WH-EVENT-3", "PAYMENT.SALE.COMPLETED", json.RawMessage(`{"amount":"10.00"}`))
+	rec2 := httptest.NewRecorder()
+	mux.ServeHTTP(rec2, req2)
+
+	if rec2.Code != http.StatusOK {
+		t.Fatalf("ServeHTTP() redelivery status = %d, want %d", rec2.Code, http.StatusOK)
+	}
+	if gotAmount != "" {
+		t.Fatal("handler ran again for a redelivered event, want it skipped via idempotency")
+	}
+}
+
+func TestWebhookMuxServeHTTPRejectsBadSignature(t *testing.T) {
+	mux := NewWebhookMux(&Client{}, "WH-1")
+	mux.On("PAYMENT.SALE.COMPLETED", func(ctx context.Context, r *testResource) error {
+		t.Fatal("handler must not run for an invalid signature")
+		return nil
+	})
+
+	req := newSignedWebhookRequest(t, "WH-1", "WH-EVENT-4", "PAYMENT.SALE.COMPLETED", json.RawMessage(`{"amount":"10.00"}`))
+	req.Header.Set("PAYPAL-TRANSMISSION-SIG", base64.StdEncoding.EncodeToString([]byte("not a real signature")))
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("ServeHTTP() status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestWebhookMuxServeHTTPReleasesClaimOnFailure(t *testing.T) {
+	mux := NewWebhookMux(&Client{}, "WH-1")
+
+	attempts := 0
+	mux.On("PAYMENT.SALE.COMPLETED", func(ctx context.Context, r *testResource) error {
+		attempts++
+		if attempts == 1 {
+			return errors.New("transient failure")
+		}
+		return nil
+	})
+
+	req1 := newSignedWebhookRequest(t, "WH-1", "WH-EVENT-5", "PAYMENT.SALE.COMPLETED", json.RawMessage(`{"amount":"5.00"}`))
+	rec1 := httptest.NewRecorder()
+	mux.ServeHTTP(rec1, req1)
+	if rec1.Code != http.StatusInternalServerError {
+		t.Fatalf("first ServeHTTP() status = %d, want %d", rec1.Code, http.StatusInternalServerError)
+	}
+
+	req2 := newSignedWebhookRequest(t, "WH-1", "WH-EVENT-5", "PAYMENT.SALE.COMPLETED", json.RawMessage(`{"amount":"5.00"}`))
+	rec2 := httptest.NewRecorder()
+	mux.ServeHTTP(rec2, req2)
+	if rec2.Code != http.StatusOK {
+		t.Fatalf("retry ServeHTTP() status = %d, want %d", rec2.Code, http.StatusOK)
+	}
+	if attempts != 2 {
+		t.Fatalf("handler ran %d times, want 2 (a retry after a failed dispatch must not be dropped)", attempts)
+	}
+}
+
+func TestWebhookMuxWithMiddleware(t *testing.T) {
+	mux := NewWebhookMux(&Client{}, "WH-1")
+	mux.On("PAYMENT.SALE.COMPLETED", func(ctx context.Context, r *testResource) error { return nil })
+
+	var order []string
+	record := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	mux.WithMiddleware(record("outer"), record("inner"))
+
+	req := newSignedWebhookRequest(t, "WH-1", "WH-EVENT-6", "PAYMENT.SALE.COMPLETED", json.RawMessage(`{}`))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if len(order) != 2 || order[0] != "outer" || order[1] != "inner" {
+		t.Fatalf("middleware ran in order %v, want [outer inner]", order)
+	}
+}
+
+func TestMemoryIdempotencyStoreTryClaimConcurrent(t *testing.T) {
+	store := NewMemoryIdempotencyStore()
+	const eventID = "WH-EVENT-RACE"
+	const n = 50
+
+	var wg sync.WaitGroup
+	claims := make([]bool, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			claims[i] = store.TryClaim(eventID)
+		}(i)
+	}
+	wg.Wait()
+
+	claimed := 0
+	for _, c := range claims {
+		if c {
+			claimed++
+		}
+	}
+	if claimed != 1 {
+		t.Fatalf("%d of %d concurrent TryClaim calls succeeded, want exactly 1", claimed, n)
+	}
+}
+
+func TestMemoryIdempotencyStoreReleaseAllowsReclaim(t *testing.T) {
+	store := NewMemoryIdempotencyStore()
+	const eventID = "WH-EVENT-7"
+
+	if !store.TryClaim(eventID) {
+		t.Fatal("TryClaim() = false on first call, want true")
+	}
+	if store.TryClaim(eventID) {
+		t.Fatal("TryClaim() = true while already claimed, want false")
+	}
+
+	store.Release(eventID)
+	if !store.TryClaim(eventID) {
+		t.Fatal("TryClaim() = false after Release, want true")
+	}
+}
@@ -0,0 +1,131 @@
+package paypalsdk
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+)
+
+// ErrWebhookURLBlocked is returned by CreateWebhook and SetWebhook when the
+// requested webhook URL uses a scheme or resolves to an address forbidden by
+// the effective WebhookURLPolicy.
+type ErrWebhookURLBlocked struct {
+	URL    string
+	Reason string
+}
+
+// Error implements error.
+func (e *ErrWebhookURLBlocked) Error() string {
+	return fmt.Sprintf("paypalsdk: webhook URL %q is blocked: %s", e.URL, e.Reason)
+}
+
+// defaultDenyCIDRs covers loopback, link-local, RFC1918 private ranges,
+// carrier-grade NAT (RFC6598), and well-known cloud metadata endpoints, for
+// both IPv4 and IPv6.
+var defaultDenyCIDRs = []string{
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"100.64.0.0/10",
+	"169.254.169.254/32",
+	"::1/128",
+	"fe80::/10",
+	"fc00::/7",
+	"fd00::/8",
+}
+
+// WebhookURLPolicy controls which webhook target URLs CreateWebhook and
+// SetWebhook will accept. The zero value rejects HTTP URLs and any URL that
+// resolves to a loopback, link-local, private, CGNAT, or metadata-service
+// address, which is the right default for webhooks that will be reachable
+// from the public internet.
+type WebhookURLPolicy struct {
+	// AllowPrivate permits webhook URLs that resolve to a loopback,
+	// link-local, private, or CGNAT address. Useful when testing against a
+	// PayPal-compatible endpoint on an internal network.
+	AllowPrivate bool
+	// AllowHTTP permits plain HTTP webhook URLs. PayPal requires HTTPS in
+	// production; this exists for sandbox/local testing.
+	AllowHTTP bool
+	// ExtraDenyCIDRs are additional CIDR blocks to reject, on top of the
+	// built-in loopback/link-local/private/CGNAT/metadata list (unless
+	// AllowPrivate is set, in which case only ExtraDenyCIDRs apply).
+	ExtraDenyCIDRs []string
+
+	// resolve looks up the IPs for a hostname. Overridable in tests; nil
+	// means net.LookupIP.
+	resolve func(host string) ([]net.IP, error)
+}
+
+func (p WebhookURLPolicy) denyNets() ([]*net.IPNet, error) {
+	var cidrs []string
+	if !p.AllowPrivate {
+		cidrs = append(cidrs, defaultDenyCIDRs...)
+	}
+	cidrs = append(cidrs, p.ExtraDenyCIDRs...)
+
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, c := range cidrs {
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			return nil, fmt.Errorf("paypalsdk: invalid deny CIDR %q: %w", c, err)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+func (p WebhookURLPolicy) lookupIP(host string) ([]net.IP, error) {
+	if p.resolve != nil {
+		return p.resolve(host)
+	}
+	return net.LookupIP(host)
+}
+
+// validate resolves rawURL and rejects it per the policy. It returns
+// *ErrWebhookURLBlocked when the URL is disallowed.
+func (p WebhookURLPolicy) validate(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("paypalsdk: invalid webhook URL %q: %w", rawURL, err)
+	}
+
+	if u.Scheme != "https" && !(p.AllowHTTP && u.Scheme == "http") {
+		return &ErrWebhookURLBlocked{URL: rawURL, Reason: "scheme must be https"}
+	}
+
+	host := u.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		return p.checkIPs(rawURL, []net.IP{ip})
+	}
+
+	ips, err := p.lookupIP(host)
+	if err != nil {
+		return fmt.Errorf("paypalsdk: unable to resolve webhook URL host %q: %w", host, err)
+	}
+	return p.checkIPs(rawURL, ips)
+}
+
+func (p WebhookURLPolicy) checkIPs(rawURL string, ips []net.IP) error {
+	denyNets, err := p.denyNets()
+	if err != nil {
+		return err
+	}
+
+	for _, ip := range ips {
+		for _, n := range denyNets {
+			if n.Contains(ip) {
+				return &ErrWebhookURLBlocked{URL: rawURL, Reason: fmt.Sprintf("resolves to %s, which is in denied range %s", ip, n)}
+			}
+		}
+	}
+	return nil
+}
+
+// checkWebhookURL validates url against c.WebhookURLPolicy, falling back to
+// the zero-value (secure-by-default) policy if none was set.
+func (c *Client) checkWebhookURL(rawURL string) error {
+	return c.WebhookURLPolicy.validate(rawURL)
+}
@@ -0,0 +1,66 @@
+package paypalsdk
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+func resolverFor(ips ...string) func(string) ([]net.IP, error) {
+	return func(string) ([]net.IP, error) {
+		parsed := make([]net.IP, 0, len(ips))
+		for _, s := range ips {
+			parsed = append(parsed, net.ParseIP(s))
+		}
+		return parsed, nil
+	}
+}
+
+func TestWebhookURLPolicyValidate(t *testing.T) {
+	cases := []struct {
+		name    string
+		policy  WebhookURLPolicy
+		rawURL  string
+		ip      string
+		wantErr bool
+	}{
+		{"public https allowed", WebhookURLPolicy{}, "https://example.com/webhook", "93.184.216.34", false},
+		{"rejects http by default", WebhookURLPolicy{}, "http://example.com/webhook", "93.184.216.34", true},
+		{"http allowed when opted in", WebhookURLPolicy{AllowHTTP: true}, "http://example.com/webhook", "93.184.216.34", false},
+		{"rejects loopback", WebhookURLPolicy{}, "https://example.com/webhook", "127.0.0.1", true},
+		{"rejects rfc1918", WebhookURLPolicy{}, "https://example.com/webhook", "10.1.2.3", true},
+		{"rejects link-local metadata", WebhookURLPolicy{}, "https://example.com/webhook", "169.254.169.254", true},
+		{"rejects cgnat", WebhookURLPolicy{}, "https://example.com/webhook", "100.64.1.1", true},
+		{"rejects ipv6 unique local", WebhookURLPolicy{}, "https://example.com/webhook", "fd00::1", true},
+		{"private allowed when opted in", WebhookURLPolicy{AllowPrivate: true}, "https://example.com/webhook", "10.1.2.3", false},
+		{"extra deny CIDR blocks public IP", WebhookURLPolicy{ExtraDenyCIDRs: []string{"93.184.216.0/24"}}, "https://example.com/webhook", "93.184.216.34", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy := tc.policy
+			policy.resolve = resolverFor(tc.ip)
+
+			err := policy.validate(tc.rawURL)
+			if tc.wantErr && err == nil {
+				t.Fatalf("validate(%q) error = nil, want an error", tc.rawURL)
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("validate(%q) error = %v, want nil", tc.rawURL, err)
+			}
+			if tc.wantErr {
+				var blocked *ErrWebhookURLBlocked
+				if !errors.As(err, &blocked) {
+					t.Fatalf("validate(%q) error type = %T, want *ErrWebhookURLBlocked", tc.rawURL, err)
+				}
+			}
+		})
+	}
+}
+
+func TestWebhookURLPolicyValidateLiteralIP(t *testing.T) {
+	var policy WebhookURLPolicy
+	if err := policy.validate("https://127.0.0.1/webhook"); err == nil {
+		t.Fatal("validate() error = nil for a literal loopback IP, want an error")
+	}
+}
@@ -0,0 +1,227 @@
+package paypalsdk
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// certCacheTTL controls how long a fetched webhook signing certificate is
+// considered valid before it is re-fetched from PAYPAL-CERT-URL.
+const certCacheTTL = 15 * time.Minute
+
+// webhookCertCache caches parsed PayPal webhook signing certificates keyed by
+// their PAYPAL-CERT-URL so that verifying a burst of events from the same
+// webhook doesn't re-fetch and re-validate the certificate on every call.
+type webhookCertCache struct {
+	mu      sync.Mutex
+	entries map[string]webhookCertCacheEntry
+}
+
+type webhookCertCacheEntry struct {
+	cert    *x509.Certificate
+	expires time.Time
+}
+
+var defaultWebhookCertCache = &webhookCertCache{
+	entries: make(map[string]webhookCertCacheEntry),
+}
+
+func (c *webhookCertCache) get(certURL string) (*x509.Certificate, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[certURL]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.cert, true
+}
+
+func (c *webhookCertCache) set(certURL string, cert *x509.Certificate) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[certURL] = webhookCertCacheEntry{
+		cert:    cert,
+		expires: time.Now().Add(certCacheTTL),
+	}
+}
+
+// VerifyWebhookSignatureLocal verifies the PAYPAL-* signature headers on an
+// inbound webhook request entirely offline, without the round-trip to
+// POST /v1/notifications/verify-webhook-signature that VerifyWebhookSignature
+// requires. It is safe to call from a high-throughput webhook receiver since
+// the signing certificate named by PAYPAL-CERT-URL is fetched once and cached.
+//
+// req.Body is read and replaced with a fresh io.ReadCloser so callers can
+// still decode the event body afterwards.
+func (c *Client) VerifyWebhookSignatureLocal(req *http.Request, webhookID string) (bool, error) {
+	transmissionID := req.Header.Get("PAYPAL-TRANSMISSION-ID")
+	transmissionTime := req.Header.Get("PAYPAL-TRANSMISSION-TIME")
+	transmissionSig := req.Header.Get("PAYPAL-TRANSMISSION-SIG")
+	certURL := req.Header.Get("PAYPAL-CERT-URL")
+	authAlgo := req.Header.Get("PAYPAL-AUTH-ALGO")
+
+	if transmissionID == "" || transmissionTime == "" || transmissionSig == "" || certURL == "" || authAlgo == "" {
+		return false, fmt.Errorf("paypalsdk: request is missing one or more PAYPAL-* signature headers")
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return false, fmt.Errorf("paypalsdk: unable to read webhook request body: %w", err)
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	cert, err := c.fetchWebhookCert(certURL)
+	if err != nil {
+		return false, err
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(transmissionSig)
+	if err != nil {
+		return false, fmt.Errorf("paypalsdk: invalid PAYPAL-TRANSMISSION-SIG: %w", err)
+	}
+
+	pub, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return false, fmt.Errorf("paypalsdk: webhook signing certificate does not contain an RSA public key")
+	}
+
+	crc := crc32.ChecksumIEEE(body)
+	payload := fmt.Sprintf("%s|%s|%s|%d", transmissionID, transmissionTime, webhookID, crc)
+
+	switch strings.ToUpper(authAlgo) {
+	case "SHA256WITHRSA":
+		sum := sha256.Sum256([]byte(payload))
+		if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, sum[:], sig); err != nil {
+			return false, nil
+		}
+		return true, nil
+	default:
+		return false, fmt.Errorf("paypalsdk: unsupported PAYPAL-AUTH-ALGO %q", authAlgo)
+	}
+}
+
+// fetchWebhookCert resolves and validates a PAYPAL-CERT-URL, returning the
+// parsed certificate from cache where possible. The URL's host must be
+// paypal.com or a subdomain of it, and the certificate must chain to a
+// trusted root.
+func (c *Client) fetchWebhookCert(certURL string) (*x509.Certificate, error) {
+	if cert, ok := defaultWebhookCertCache.get(certURL); ok {
+		return cert, nil
+	}
+
+	if err := validateWebhookCertURL(certURL); err != nil {
+		return nil, err
+	}
+
+	httpClient := c.WebhookCertHTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Get(certURL)
+	if err != nil {
+		return nil, fmt.Errorf("paypalsdk: unable to fetch webhook signing certificate: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("paypalsdk: unable to fetch webhook signing certificate: unexpected status %s", resp.Status)
+	}
+
+	pemBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("paypalsdk: unable to read webhook signing certificate: %w", err)
+	}
+
+	cert, intermediates, err := parseWebhookCertChain(pemBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	roots, err := x509.SystemCertPool()
+	if err != nil || roots == nil {
+		roots = x509.NewCertPool()
+	}
+	if err := verifyWebhookCertChain(cert, intermediates, roots); err != nil {
+		return nil, err
+	}
+
+	defaultWebhookCertCache.set(certURL, cert)
+	return cert, nil
+}
+
+// parseWebhookCertChain parses a PAYPAL-CERT-URL response into the leaf
+// certificate and a pool of any intermediates that follow it. PayPal serves
+// the leaf cert first, followed by the intermediates needed to chain it to a
+// public root; real leaf certs are essentially never signed directly by a
+// root in the system trust store.
+func parseWebhookCertChain(pemBytes []byte) (leaf *x509.Certificate, intermediates *x509.CertPool, err error) {
+	var certs []*x509.Certificate
+	rest := pemBytes
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("paypalsdk: unable to parse webhook signing certificate: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, nil, fmt.Errorf("paypalsdk: PAYPAL-CERT-URL did not return a PEM encoded certificate")
+	}
+
+	intermediates = x509.NewCertPool()
+	for _, ic := range certs[1:] {
+		intermediates.AddCert(ic)
+	}
+	return certs[0], intermediates, nil
+}
+
+// verifyWebhookCertChain verifies that leaf chains to roots through
+// intermediates.
+func verifyWebhookCertChain(leaf *x509.Certificate, intermediates, roots *x509.CertPool) error {
+	if _, err := leaf.Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates}); err != nil {
+		return fmt.Errorf("paypalsdk: webhook signing certificate does not chain to a trusted root: %w", err)
+	}
+	return nil
+}
+
+// validateWebhookCertURL rejects anything that isn't an HTTPS URL on
+// paypal.com or a paypal.com subdomain (e.g. api.paypal.com,
+// sandbox.paypal.com), preventing a forged PAYPAL-CERT-URL header from
+// pointing the verifier at an attacker-controlled certificate.
+func validateWebhookCertURL(certURL string) error {
+	u, err := url.Parse(certURL)
+	if err != nil {
+		return fmt.Errorf("paypalsdk: invalid PAYPAL-CERT-URL: %w", err)
+	}
+	if u.Scheme != "https" {
+		return fmt.Errorf("paypalsdk: PAYPAL-CERT-URL must use https")
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host != "paypal.com" && !strings.HasSuffix(host, ".paypal.com") {
+		return fmt.Errorf("paypalsdk: PAYPAL-CERT-URL host %q is not a paypal.com host", host)
+	}
+
+	return nil
+}
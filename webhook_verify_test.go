@@ -0,0 +1,247 @@
+package paypalsdk
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"hash/crc32"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// genWebhookCertChain builds a throwaway root CA, an intermediate signed by
+// it, and a leaf signed by the intermediate, mirroring the chain PayPal
+// serves from PAYPAL-CERT-URL. It returns the leaf+intermediate PEM bundle
+// (in the order PayPal serves them) and a root pool containing only the CA.
+func genWebhookCertChain(t *testing.T) (pemBundle []byte, roots *x509.CertPool) {
+	t.Helper()
+
+	rootKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey(root) error = %v", err)
+	}
+	rootTpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Root CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+	}
+	rootDER, err := x509.CreateCertificate(rand.Reader, rootTpl, rootTpl, &rootKey.PublicKey, rootKey)
+	if err != nil {
+		t.Fatalf("CreateCertificate(root) error = %v", err)
+	}
+	rootCert, err := x509.ParseCertificate(rootDER)
+	if err != nil {
+		t.Fatalf("ParseCertificate(root) error = %v", err)
+	}
+
+	intKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey(intermediate) error = %v", err)
+	}
+	intTpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(2),
+		Subject:               pkix.Name{CommonName: "Test Intermediate CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+	}
+	intDER, err := x509.CreateCertificate(rand.Reader, intTpl, rootCert, &intKey.PublicKey, rootKey)
+	if err != nil {
+		t.Fatalf("CreateCertificate(intermediate) error = %v", err)
+	}
+	intCert, err := x509.ParseCertificate(intDER)
+	if err != nil {
+		t.Fatalf("ParseCertificate(intermediate) error = %v", err)
+	}
+
+	leafKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey(leaf) error = %v", err)
+	}
+	leafTpl := &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "api.paypal.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTpl, intCert, &leafKey.PublicKey, intKey)
+	if err != nil {
+		t.Fatalf("CreateCertificate(leaf) error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	for _, der := range [][]byte{leafDER, intDER} {
+		if err := pem.Encode(&buf, &pem.Block{Type: "CERTIFICATE", Bytes: der}); err != nil {
+			t.Fatalf("pem.Encode() error = %v", err)
+		}
+	}
+
+	roots = x509.NewCertPool()
+	roots.AddCert(rootCert)
+
+	return buf.Bytes(), roots
+}
+
+func TestParseWebhookCertChain(t *testing.T) {
+	pemBundle, _ := genWebhookCertChain(t)
+
+	leaf, intermediates, err := parseWebhookCertChain(pemBundle)
+	if err != nil {
+		t.Fatalf("parseWebhookCertChain() error = %v", err)
+	}
+	if leaf.Subject.CommonName != "api.paypal.com" {
+		t.Fatalf("leaf.Subject.CommonName = %q, want %q", leaf.Subject.CommonName, "api.paypal.com")
+	}
+	if len(intermediates.Subjects()) != 1 { //nolint:staticcheck // Subjects() is the simplest way to assert pool size here
+		t.Fatalf("intermediates pool has %d entries, want 1", len(intermediates.Subjects()))
+	}
+}
+
+func TestParseWebhookCertChainEmpty(t *testing.T) {
+	if _, _, err := parseWebhookCertChain([]byte("not a pem file")); err == nil {
+		t.Fatal("parseWebhookCertChain() error = nil for non-PEM input, want an error")
+	}
+}
+
+func TestVerifyWebhookCertChain(t *testing.T) {
+	pemBundle, roots := genWebhookCertChain(t)
+
+	leaf, intermediates, err := parseWebhookCertChain(pemBundle)
+	if err != nil {
+		t.Fatalf("parseWebhookCertChain() error = %v", err)
+	}
+
+	if err := verifyWebhookCertChain(leaf, intermediates, roots); err != nil {
+		t.Fatalf("verifyWebhookCertChain() error = %v, want nil (leaf should chain to roots via intermediates)", err)
+	}
+
+	// Dropping the intermediates must break the chain: real leaf certs are
+	// never signed directly by a root, so this is the regression the
+	// original single-block pem.Decode would have hit in production.
+	if err := verifyWebhookCertChain(leaf, x509.NewCertPool(), roots); err == nil {
+		t.Fatal("verifyWebhookCertChain() error = nil without intermediates, want an error")
+	}
+}
+
+func TestValidateWebhookCertURL(t *testing.T) {
+	cases := []struct {
+		name    string
+		certURL string
+		wantErr bool
+	}{
+		{"valid api host", "https://api.paypal.com/v1/notifications/certs/CERT-1", false},
+		{"valid sandbox host", "https://api.sandbox.paypal.com/v1/notifications/certs/CERT-1", false},
+		{"bare apex host", "https://paypal.com/cert", false},
+		{"rejects http", "http://api.paypal.com/v1/notifications/certs/CERT-1", true},
+		{"rejects foreign host", "https://evil.com/v1/notifications/certs/CERT-1", true},
+		{"rejects paypal.com lookalike", "https://paypal.com.evil.com/cert", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateWebhookCertURL(tc.certURL)
+			if tc.wantErr && err == nil {
+				t.Fatalf("expected error for %q, got nil", tc.certURL)
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("unexpected error for %q: %v", tc.certURL, err)
+			}
+		})
+	}
+}
+
+func TestWebhookCertCacheExpiry(t *testing.T) {
+	cache := &webhookCertCache{entries: make(map[string]webhookCertCacheEntry)}
+	cert := &x509.Certificate{}
+
+	cache.set("https://api.paypal.com/cert", cert)
+	if _, ok := cache.get("https://api.paypal.com/cert"); !ok {
+		t.Fatal("expected cache hit right after set")
+	}
+
+	cache.entries["https://api.paypal.com/cert"] = webhookCertCacheEntry{
+		cert:    cert,
+		expires: time.Now().Add(-time.Second),
+	}
+	if _, ok := cache.get("https://api.paypal.com/cert"); ok {
+		t.Fatal("expected cache miss once the entry has expired")
+	}
+}
+
+func signWebhookPayload(t *testing.T, key *rsa.PrivateKey, transmissionID, transmissionTime, webhookID string, body []byte) []byte {
+	t.Helper()
+
+	crc := crc32.ChecksumIEEE(body)
+	payload := fmt.Sprintf("%s|%s|%s|%d", transmissionID, transmissionTime, webhookID, crc)
+	sum := sha256.Sum256([]byte(payload))
+
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+	if err != nil {
+		t.Fatalf("SignPKCS1v15() error = %v", err)
+	}
+	return sig
+}
+
+func TestVerifyWebhookSignatureLocal(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	defaultWebhookCertCache.set(
+		"https://api.paypal.com/v1/notifications/certs/CERT-TEST",
+		&x509.Certificate{PublicKey: &key.PublicKey},
+	)
+
+	const certURL = "https://api.paypal.com/v1/notifications/certs/CERT-TEST"
+	const transmissionID = "transmission-1"
+	const transmissionTime = "2026-07-26T00:00:00Z"
+	const webhookID = "WH-1"
+	body := []byte(`{"id":"WH-EVENT-1"}`)
+
+	sig := signWebhookPayload(t, key, transmissionID, transmissionTime, webhookID, body)
+
+	newReq := func(b []byte) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "https://example.com/webhook", bytes.NewReader(b))
+		req.Header.Set("PAYPAL-TRANSMISSION-ID", transmissionID)
+		req.Header.Set("PAYPAL-TRANSMISSION-TIME", transmissionTime)
+		req.Header.Set("PAYPAL-TRANSMISSION-SIG", base64.StdEncoding.EncodeToString(sig))
+		req.Header.Set("PAYPAL-CERT-URL", certURL)
+		req.Header.Set("PAYPAL-AUTH-ALGO", "SHA256withRSA")
+		return req
+	}
+
+	c := &Client{}
+
+	ok, err := c.VerifyWebhookSignatureLocal(newReq(body), webhookID)
+	if err != nil {
+		t.Fatalf("VerifyWebhookSignatureLocal() error = %v", err)
+	}
+	if !ok {
+		t.Fatal("VerifyWebhookSignatureLocal() = false, want true for a validly signed request")
+	}
+
+	ok, err = c.VerifyWebhookSignatureLocal(newReq([]byte(`{"id":"tampered"}`)), webhookID)
+	if err != nil {
+		t.Fatalf("VerifyWebhookSignatureLocal() error = %v", err)
+	}
+	if ok {
+		t.Fatal("VerifyWebhookSignatureLocal() = true, want false for a tampered body")
+	}
+}